@@ -0,0 +1,346 @@
+package plugin
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/pkg/errors"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+const (
+	// defaultPageSize is used when a list request doesn't specify one.
+	defaultPageSize = 20
+	// maxPageSize caps how many polls a single page can return.
+	maxPageSize = 100
+)
+
+// InitAPI builds the router for the plugin's REST API. It is called once
+// from OnActivate and the result is stored on MatterpollPlugin.router.
+func (p *MatterpollPlugin) InitAPI() *mux.Router {
+	r := mux.NewRouter()
+	apiV1 := r.PathPrefix("/api/v1").Subrouter()
+	apiV1.Use(p.withUser)
+
+	apiV1.HandleFunc("/polls", p.handleListPolls).Methods(http.MethodGet)
+	apiV1.HandleFunc("/polls", p.handleCreatePoll).Methods(http.MethodPost)
+	apiV1.HandleFunc("/polls/{id}", p.handleGetPoll).Methods(http.MethodGet)
+	apiV1.HandleFunc("/polls/{id}", p.handleDeletePoll).Methods(http.MethodDelete)
+	apiV1.HandleFunc("/polls/{id}/results", p.handleGetResults).Methods(http.MethodGet)
+	apiV1.HandleFunc("/config", p.handleGetConfig).Methods(http.MethodGet)
+
+	return r
+}
+
+// handleGetConfig exposes the plugin's effective configuration, so admins
+// and integrations can confirm what a hot reload actually applied.
+func (p *MatterpollPlugin) handleGetConfig(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-Id")
+	if !p.API.HasPermissionTo(userID, model.PERMISSION_MANAGE_SYSTEM) {
+		p.writeAPIError(w, http.StatusForbidden, errors.New("not authorized to view the plugin configuration"))
+		return
+	}
+
+	p.writeAPIResponse(w, http.StatusOK, configResponse{
+		configuration: p.getConfiguration(),
+		Features:      p.features,
+	})
+}
+
+// configResponse is what GET /api/v1/config returns: the admin-set
+// configuration alongside the features the running server actually granted,
+// so clients can tell a hot reload applied from a feature that's simply
+// unavailable on this server.
+type configResponse struct {
+	*configuration
+	Features features `json:"features"`
+}
+
+// withUser rejects requests the Mattermost server hasn't attached a user to,
+// and makes the user id available to downstream handlers.
+func (p *MatterpollPlugin) withUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Header.Get("Mattermost-User-Id")
+		if userID == "" {
+			p.writeAPIError(w, http.StatusUnauthorized, errors.New("not authorized"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// envelope wraps every list response with cursor links, so clients can page
+// through results without relying on offsets that shift as polls are added.
+type envelope struct {
+	Data interface{} `json:"data"`
+	Self string      `json:"self"`
+	Next string      `json:"next,omitempty"`
+	// Prev is the cursor to pass to get the previous page. It is the empty
+	// string when that page is the first page, which is reached by omitting
+	// the cursor param rather than by any encoded value — HasPrev is what
+	// distinguishes that from there being no previous page at all.
+	Prev    string `json:"prev,omitempty"`
+	HasPrev bool   `json:"has_prev"`
+}
+
+// cursor is the opaque pagination token, base64-encoded over the wire.
+type cursor struct {
+	CreatedAt int64  `json:"created_at"`
+	ID        string `json:"id"`
+}
+
+func encodeCursor(c cursor) (string, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	b, err := base64.URLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(b, &c)
+	return c, err
+}
+
+func (p *MatterpollPlugin) handleListPolls(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-Id")
+	q := r.URL.Query()
+
+	targetUserID := q.Get("user_id")
+	if targetUserID == "" {
+		targetUserID = userID
+	}
+	if targetUserID != userID && !p.API.HasPermissionTo(userID, model.PERMISSION_MANAGE_SYSTEM) {
+		p.writeAPIError(w, http.StatusForbidden, errors.New("not authorized to list another user's polls"))
+		return
+	}
+
+	pageSize := defaultPageSize
+	if raw := q.Get("page_size"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 && n <= maxPageSize {
+			pageSize = n
+		}
+	}
+
+	var after *cursor
+	if raw := q.Get("cursor"); raw != "" {
+		c, err := decodeCursor(raw)
+		if err != nil {
+			p.writeAPIError(w, http.StatusBadRequest, errors.Wrap(err, "invalid cursor"))
+			return
+		}
+		after = &c
+	}
+
+	polls, err := p.Store.ListPollsForUser(targetUserID)
+	if err != nil {
+		p.writeAPIError(w, http.StatusInternalServerError, errors.Wrap(err, "failed to list polls"))
+		return
+	}
+
+	start := 0
+	if after != nil {
+		for i, poll := range polls {
+			if poll.CreatedAt == after.CreatedAt && poll.ID == after.ID {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	end := start + pageSize
+	if end > len(polls) {
+		end = len(polls)
+	}
+	page := polls[start:end]
+
+	env := envelope{Data: page, Self: q.Get("cursor")}
+	if end < len(polls) {
+		last := page[len(page)-1]
+		if next, err := encodeCursor(cursor{CreatedAt: last.CreatedAt, ID: last.ID}); err == nil {
+			env.Next = next
+		}
+	}
+	if start > 0 {
+		// The previous page starts pageSize back from here (clamped to 0).
+		// Its anchor cursor is the poll just before that boundary, or the
+		// empty string when the previous page is the first page, which is
+		// reached by omitting the cursor param entirely.
+		prevStart := start - pageSize
+		if prevStart < 0 {
+			prevStart = 0
+		}
+		if prevStart == 0 {
+			env.HasPrev = true
+		} else if prevAnchor, err := encodeCursor(cursor{CreatedAt: polls[prevStart-1].CreatedAt, ID: polls[prevStart-1].ID}); err == nil {
+			env.Prev = prevAnchor
+			env.HasPrev = true
+		}
+	}
+
+	p.writeAPIResponse(w, http.StatusOK, env)
+}
+
+type createPollRequest struct {
+	Question      string   `json:"question"`
+	AnswerOptions []string `json:"answer_options"`
+}
+
+func (p *MatterpollPlugin) handleCreatePoll(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-Id")
+
+	if allowed, err := p.userMayCreatePoll(userID); err != nil {
+		p.writeAPIError(w, http.StatusInternalServerError, errors.Wrap(err, "failed to check poll creation permission"))
+		return
+	} else if !allowed {
+		p.writeAPIError(w, http.StatusForbidden, errors.New("your role is not allowed to create polls"))
+		return
+	}
+
+	var req createPollRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		p.writeAPIError(w, http.StatusBadRequest, errors.Wrap(err, "invalid request body"))
+		return
+	}
+	if req.Question == "" || len(req.AnswerOptions) < 2 {
+		p.writeAPIError(w, http.StatusBadRequest, errors.New("a poll needs a question and at least two answer options"))
+		return
+	}
+
+	answerOptions := make([]*poll.AnswerOption, len(req.AnswerOptions))
+	for i, a := range req.AnswerOptions {
+		answerOptions[i] = &poll.AnswerOption{Answer: a}
+	}
+
+	newPoll := &poll.Poll{
+		ID:            model.NewId(),
+		CreatedAt:     time.Now().Unix(),
+		Creator:       userID,
+		Question:      req.Question,
+		AnswerOptions: answerOptions,
+		Settings:      p.getConfiguration().DefaultPollSettings,
+	}
+
+	if err := p.Store.SavePoll(newPoll); err != nil {
+		p.writeAPIError(w, http.StatusInternalServerError, errors.Wrap(err, "failed to save poll"))
+		return
+	}
+
+	p.writeAPIResponse(w, http.StatusCreated, newPoll)
+}
+
+// userMayCreatePoll enforces configuration.AllowedRoles: when it's empty,
+// any authenticated user may create polls; otherwise the user must hold at
+// least one of the listed roles.
+func (p *MatterpollPlugin) userMayCreatePoll(userID string) (bool, error) {
+	allowedRoles := p.getConfiguration().AllowedRoles
+	if len(allowedRoles) == 0 {
+		return true, nil
+	}
+
+	user, appErr := p.API.GetUser(userID)
+	if appErr != nil {
+		return false, appErr
+	}
+
+	userRoles := strings.Fields(user.Roles)
+	for _, allowed := range allowedRoles {
+		for _, role := range userRoles {
+			if role == allowed {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// userMayAccessPoll reports whether userID is allowed to read or modify
+// storedPoll: its creator, or a user with system-admin permissions. Polls
+// aren't public, so every single-poll endpoint enforces this the same way.
+func (p *MatterpollPlugin) userMayAccessPoll(storedPoll *poll.Poll, userID string) bool {
+	return storedPoll.Creator == userID || p.API.HasPermissionTo(userID, model.PERMISSION_MANAGE_SYSTEM)
+}
+
+func (p *MatterpollPlugin) handleGetPoll(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-Id")
+	id := mux.Vars(r)["id"]
+
+	storedPoll, err := p.Store.Poll(id)
+	if err != nil {
+		p.writeAPIError(w, http.StatusNotFound, errors.Wrap(err, "poll not found"))
+		return
+	}
+	if !p.userMayAccessPoll(storedPoll, userID) {
+		p.writeAPIError(w, http.StatusForbidden, errors.New("not authorized to view this poll"))
+		return
+	}
+
+	p.writeAPIResponse(w, http.StatusOK, storedPoll)
+}
+
+func (p *MatterpollPlugin) handleGetResults(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-Id")
+	id := mux.Vars(r)["id"]
+
+	storedPoll, err := p.Store.Poll(id)
+	if err != nil {
+		p.writeAPIError(w, http.StatusNotFound, errors.Wrap(err, "poll not found"))
+		return
+	}
+	if !p.userMayAccessPoll(storedPoll, userID) {
+		p.writeAPIError(w, http.StatusForbidden, errors.New("not authorized to view this poll"))
+		return
+	}
+
+	p.writeAPIResponse(w, http.StatusOK, storedPoll.AnswerOptions)
+}
+
+func (p *MatterpollPlugin) handleDeletePoll(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-Id")
+	id := mux.Vars(r)["id"]
+
+	storedPoll, err := p.Store.Poll(id)
+	if err != nil {
+		p.writeAPIError(w, http.StatusNotFound, errors.Wrap(err, "poll not found"))
+		return
+	}
+	if !p.userMayAccessPoll(storedPoll, userID) {
+		p.writeAPIError(w, http.StatusForbidden, errors.New("not authorized to delete this poll"))
+		return
+	}
+
+	if err := p.Store.DeletePoll(id); err != nil {
+		p.writeAPIError(w, http.StatusInternalServerError, errors.Wrap(err, "failed to delete poll"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (p *MatterpollPlugin) writeAPIResponse(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		p.API.LogWarn("failed to write API response", "error", err.Error())
+	}
+}
+
+type apiErrorResponse struct {
+	Message string `json:"message"`
+}
+
+func (p *MatterpollPlugin) writeAPIError(w http.ResponseWriter, status int, err error) {
+	p.writeAPIResponse(w, status, apiErrorResponse{Message: err.Error()})
+}
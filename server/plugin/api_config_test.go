@@ -0,0 +1,41 @@
+package plugin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestAPIGetConfigIncludesFeatures(t *testing.T) {
+	api := &plugintest.API{}
+	p := setupTestPlugin(t, api, &mockstore.Store{}, testutils.GetSiteURL())
+	p.features = features{BotAccounts: true, DialogElements: false}
+
+	api.On("HasPermissionTo", "userID1", model.PERMISSION_MANAGE_SYSTEM).Return(true)
+	defer api.AssertExpectations(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/config", nil)
+	r.Header.Set("Mattermost-User-Id", "userID1")
+	w := httptest.NewRecorder()
+
+	p.router.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// configResponse embeds an unexported pointer type, which encoding/json
+	// can only decode into once it has been allocated.
+	resp := configResponse{configuration: &configuration{}}
+	require.Nil(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.Features.BotAccounts)
+	assert.False(t, resp.Features.DialogElements)
+	assert.Equal(t, "poll", resp.Trigger)
+}
@@ -0,0 +1,189 @@
+package plugin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func TestAPICreatePollRequiresUser(t *testing.T) {
+	api := &plugintest.API{}
+	p := setupTestPlugin(t, api, &mockstore.Store{}, testutils.GetSiteURL())
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/polls", strings.NewReader(`{}`))
+	w := httptest.NewRecorder()
+
+	p.router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAPICreatePoll(t *testing.T) {
+	api := &plugintest.API{}
+	store := &mockstore.Store{}
+	p := setupTestPlugin(t, api, store, testutils.GetSiteURL())
+
+	store.On("SavePoll", mock.AnythingOfType("*poll.Poll")).Return(nil)
+
+	body := `{"question":"what now?","answer_options":["a","b"]}`
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/polls", strings.NewReader(body))
+	r.Header.Set("Mattermost-User-Id", "userID1")
+	w := httptest.NewRecorder()
+
+	p.router.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	store.AssertExpectations(t)
+}
+
+func TestAPIGetPollNotFound(t *testing.T) {
+	api := &plugintest.API{}
+	store := &mockstore.Store{}
+	p := setupTestPlugin(t, api, store, testutils.GetSiteURL())
+
+	store.On("Poll", "pollID1").Return(nil, assert.AnError)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/polls/pollID1", nil)
+	r.Header.Set("Mattermost-User-Id", "userID1")
+	w := httptest.NewRecorder()
+
+	p.router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	store.AssertExpectations(t)
+}
+
+func TestAPIGetPollForbiddenForNonCreator(t *testing.T) {
+	api := &plugintest.API{}
+	store := &mockstore.Store{}
+	p := setupTestPlugin(t, api, store, testutils.GetSiteURL())
+
+	store.On("Poll", "pollID1").Return(&poll.Poll{ID: "pollID1", Creator: "userID1"}, nil)
+	api.On("HasPermissionTo", "userID2", model.PERMISSION_MANAGE_SYSTEM).Return(false)
+	defer api.AssertExpectations(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/polls/pollID1", nil)
+	r.Header.Set("Mattermost-User-Id", "userID2")
+	w := httptest.NewRecorder()
+
+	p.router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	store.AssertExpectations(t)
+}
+
+func TestAPIGetResultsForbiddenForNonCreator(t *testing.T) {
+	api := &plugintest.API{}
+	store := &mockstore.Store{}
+	p := setupTestPlugin(t, api, store, testutils.GetSiteURL())
+
+	store.On("Poll", "pollID1").Return(&poll.Poll{ID: "pollID1", Creator: "userID1"}, nil)
+	api.On("HasPermissionTo", "userID2", model.PERMISSION_MANAGE_SYSTEM).Return(false)
+	defer api.AssertExpectations(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/polls/pollID1/results", nil)
+	r.Header.Set("Mattermost-User-Id", "userID2")
+	w := httptest.NewRecorder()
+
+	p.router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	store.AssertExpectations(t)
+}
+
+func TestAPIGetPollAllowedForAdmin(t *testing.T) {
+	api := &plugintest.API{}
+	store := &mockstore.Store{}
+	p := setupTestPlugin(t, api, store, testutils.GetSiteURL())
+
+	store.On("Poll", "pollID1").Return(&poll.Poll{ID: "pollID1", Creator: "userID1"}, nil)
+	api.On("HasPermissionTo", "userID2", model.PERMISSION_MANAGE_SYSTEM).Return(true)
+	defer api.AssertExpectations(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/polls/pollID1", nil)
+	r.Header.Set("Mattermost-User-Id", "userID2")
+	w := httptest.NewRecorder()
+
+	p.router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	store.AssertExpectations(t)
+}
+
+func fetchPollsPage(t *testing.T, p *MatterpollPlugin, query string) envelope {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/polls?"+query, nil)
+	r.Header.Set("Mattermost-User-Id", "userID1")
+	w := httptest.NewRecorder()
+	p.router.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var env envelope
+	require.Nil(t, json.Unmarshal(w.Body.Bytes(), &env))
+	return env
+}
+
+func pagePollIDs(t *testing.T, env envelope) []string {
+	b, err := json.Marshal(env.Data)
+	require.Nil(t, err)
+	var polls []*poll.Poll
+	require.Nil(t, json.Unmarshal(b, &polls))
+	ids := make([]string, len(polls))
+	for i, p := range polls {
+		ids[i] = p.ID
+	}
+	return ids
+}
+
+func TestAPIListPollsPagination(t *testing.T) {
+	api := &plugintest.API{}
+	store := &mockstore.Store{}
+	p := setupTestPlugin(t, api, store, testutils.GetSiteURL())
+
+	// ListPollsForUser is documented (and, since the kvstore fix, guaranteed)
+	// to return most-recent-first.
+	polls := []*poll.Poll{
+		{ID: "pollID4", CreatedAt: 400, Creator: "userID1"},
+		{ID: "pollID3", CreatedAt: 300, Creator: "userID1"},
+		{ID: "pollID2", CreatedAt: 200, Creator: "userID1"},
+		{ID: "pollID1", CreatedAt: 100, Creator: "userID1"},
+		{ID: "pollID0", CreatedAt: 0, Creator: "userID1"},
+	}
+	store.On("ListPollsForUser", "userID1").Return(polls, nil)
+
+	firstPage := fetchPollsPage(t, p, "page_size=2")
+	assert.Empty(t, firstPage.Self)
+	assert.NotEmpty(t, firstPage.Next)
+	assert.False(t, firstPage.HasPrev)
+	assert.Equal(t, []string{"pollID4", "pollID3"}, pagePollIDs(t, firstPage))
+
+	secondPage := fetchPollsPage(t, p, "page_size=2&cursor="+firstPage.Next)
+	assert.Equal(t, firstPage.Next, secondPage.Self)
+	assert.NotEmpty(t, secondPage.Next)
+	assert.True(t, secondPage.HasPrev)
+	assert.Equal(t, []string{"pollID2", "pollID1"}, pagePollIDs(t, secondPage))
+
+	thirdPage := fetchPollsPage(t, p, "page_size=2&cursor="+secondPage.Next)
+	assert.Empty(t, thirdPage.Next)
+	assert.True(t, thirdPage.HasPrev)
+	assert.Equal(t, []string{"pollID0"}, pagePollIDs(t, thirdPage))
+
+	// Following thirdPage.Prev must land back on exactly secondPage, not
+	// reload thirdPage itself.
+	backToSecondPage := fetchPollsPage(t, p, "page_size=2&cursor="+thirdPage.Prev)
+	assert.Equal(t, secondPage.Self, backToSecondPage.Self)
+	assert.Equal(t, []string{"pollID2", "pollID1"}, pagePollIDs(t, backToSecondPage))
+
+	store.AssertExpectations(t)
+}
@@ -0,0 +1,23 @@
+package plugin
+
+import "github.com/mattermost/mattermost-server/model"
+
+// getCommand builds the slash command registration for the given trigger.
+// The autocomplete hint degrades based on p.features: servers too old for
+// interactive dialogs (DialogElements) only get the plain-text argument
+// form, since the plugin can't open a poll-builder dialog on them.
+func (p *MatterpollPlugin) getCommand(trigger string) *model.Command {
+	hint := "[question] [answer1] [answer2]..."
+	if p.features.DialogElements {
+		hint += " (or run with no arguments to open the poll builder dialog)"
+	}
+
+	return &model.Command{
+		Trigger:          trigger,
+		AutoComplete:     true,
+		AutoCompleteDesc: "Create a poll",
+		AutoCompleteHint: hint,
+		DisplayName:      "Matterpoll",
+		Description:      "Create a poll for your team.",
+	}
+}
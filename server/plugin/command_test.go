@@ -0,0 +1,17 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCommandHintReflectsDialogElementsFeature(t *testing.T) {
+	p := &MatterpollPlugin{}
+
+	p.features = features{DialogElements: false}
+	assert.NotContains(t, p.getCommand("poll").AutoCompleteHint, "poll builder dialog")
+
+	p.features = features{DialogElements: true}
+	assert.Contains(t, p.getCommand("poll").AutoCompleteHint, "poll builder dialog")
+}
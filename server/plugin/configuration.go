@@ -0,0 +1,101 @@
+package plugin
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+// configuration captures the plugin's admin console settings. It is treated
+// as immutable: call setConfiguration to replace it wholesale, never mutate
+// a configuration in place.
+type configuration struct {
+	Trigger string
+
+	// StoreBackend selects which store.Store implementation OnActivate wires
+	// up: "kv" (default) or "sql". See store/kvstore and store/sqlstore.
+	StoreBackend string
+
+	// AllowedRoles restricts who may create polls via the REST API, e.g.
+	// "system_admin". Empty means any authenticated user.
+	AllowedRoles []string
+
+	// DefaultPollSettings seeds poll.Settings for polls created without
+	// explicit settings, e.g. via the REST API.
+	DefaultPollSettings poll.Settings
+}
+
+// storeBackendKV and storeBackendSQL are the only valid values for StoreBackend.
+const (
+	storeBackendKV  = "kv"
+	storeBackendSQL = "sql"
+)
+
+// reservedTriggers can't be used as the slash command trigger because they
+// either collide with built-in Mattermost commands or would be confusing.
+var reservedTriggers = map[string]bool{
+	"poll":     false, // the plugin's own default, explicitly allowed
+	"msg":      true,
+	"shrug":    true,
+	"search":   true,
+	"invite":   true,
+	"settings": true,
+}
+
+// Clone returns a copy of the configuration with its own copy of AllowedRoles.
+func (c *configuration) Clone() *configuration {
+	clone := *c
+	clone.AllowedRoles = append([]string(nil), c.AllowedRoles...)
+	return &clone
+}
+
+// Validate checks the configuration is safe to activate. It never mutates c.
+func (c *configuration) Validate() error {
+	trigger := strings.TrimSpace(c.Trigger)
+	if trigger == "" {
+		return errors.New("trigger must not be empty")
+	}
+	if trigger != c.Trigger {
+		return errors.New("trigger must not contain leading or trailing whitespace")
+	}
+	if strings.ContainsAny(trigger, " \t\n") {
+		return errors.New("trigger must not contain whitespace")
+	}
+	if reservedTriggers[trigger] {
+		return errors.Errorf("trigger %q is reserved", trigger)
+	}
+
+	switch c.StoreBackend {
+	case "", storeBackendKV, storeBackendSQL:
+	default:
+		return errors.Errorf("unknown store backend %q", c.StoreBackend)
+	}
+
+	return nil
+}
+
+// getConfiguration atomically loads the active configuration. The returned
+// struct is shared and must not be mutated by the caller. It panics if no
+// configuration has been set yet, see getConfigurationOrNil.
+func (p *MatterpollPlugin) getConfiguration() *configuration {
+	return p.configuration.Load().(*configuration)
+}
+
+// getConfigurationOrNil is like getConfiguration but safe to call before any
+// configuration has ever been set, e.g. on the very first OnConfigurationChange.
+func (p *MatterpollPlugin) getConfigurationOrNil() *configuration {
+	v := p.configuration.Load()
+	if v == nil {
+		return nil
+	}
+	return v.(*configuration)
+}
+
+// setConfiguration replaces the active configuration. A configuration that is
+// already stored as p.configuration is never copied, so as to provide
+// a chance for pointer equality checks elsewhere to succeed.
+func (p *MatterpollPlugin) setConfiguration(configuration *configuration) {
+	p.configuration.Store(configuration)
+}
@@ -0,0 +1,47 @@
+package plugin
+
+import "github.com/pkg/errors"
+
+// OnConfigurationChange is called by the server whenever the plugin's admin
+// console settings change, including once before OnActivate. It
+// validates the incoming configuration and, if the trigger changed,
+// atomically swaps the registered slash command: unregister old, register
+// new, and roll back to the old trigger if registration of the new one
+// fails. The plugin is left without a registered command only if that
+// rollback registration fails as well.
+func (p *MatterpollPlugin) OnConfigurationChange() error {
+	var newConfiguration configuration
+	if err := p.API.LoadPluginConfiguration(&newConfiguration); err != nil {
+		return errors.Wrap(err, "failed to load plugin configuration")
+	}
+	if err := newConfiguration.Validate(); err != nil {
+		return errors.Wrap(err, "invalid configuration")
+	}
+
+	oldConfiguration := p.getConfigurationOrNil()
+	if oldConfiguration != nil && oldConfiguration.Trigger == newConfiguration.Trigger {
+		p.setConfiguration(&newConfiguration)
+		return nil
+	}
+
+	if oldConfiguration != nil {
+		if err := p.API.UnregisterCommand("", oldConfiguration.Trigger); err != nil {
+			return errors.Wrap(err, "failed to unregister previous command")
+		}
+	}
+
+	if err := p.API.RegisterCommand(p.getCommand(newConfiguration.Trigger)); err != nil {
+		if oldConfiguration == nil {
+			return errors.Wrap(err, "failed to register command")
+		}
+		// Roll back so the plugin doesn't end up with no command registered
+		// at all, just because the new trigger was rejected.
+		if rerr := p.API.RegisterCommand(p.getCommand(oldConfiguration.Trigger)); rerr != nil {
+			return errors.Wrap(rerr, "failed to register new command, and rollback to previous trigger also failed")
+		}
+		return errors.Wrap(err, "failed to register new command, rolled back to previous trigger")
+	}
+
+	p.setConfiguration(&newConfiguration)
+	return nil
+}
@@ -0,0 +1,90 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func loadPluginConfigurationInto(trigger string) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		out := args.Get(0).(*configuration)
+		out.Trigger = trigger
+	}
+}
+
+func TestOnConfigurationChangeFirstActivation(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("LoadPluginConfiguration", mock.AnythingOfType("*plugin.configuration")).
+		Run(loadPluginConfigurationInto("poll")).Return(nil)
+	api.On("RegisterCommand", mock.AnythingOfType("*model.Command")).Return(nil)
+	defer api.AssertExpectations(t)
+
+	p := &MatterpollPlugin{}
+	p.SetAPI(api)
+
+	err := p.OnConfigurationChange()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "poll", p.getConfiguration().Trigger)
+}
+
+func TestOnConfigurationChangeSwapsTrigger(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("LoadPluginConfiguration", mock.AnythingOfType("*plugin.configuration")).
+		Run(loadPluginConfigurationInto("survey")).Return(nil)
+	api.On("UnregisterCommand", "", "poll").Return(nil)
+	api.On("RegisterCommand", mock.AnythingOfType("*model.Command")).Return(nil)
+	defer api.AssertExpectations(t)
+
+	p := &MatterpollPlugin{}
+	p.SetAPI(api)
+	p.setConfiguration(&configuration{Trigger: "poll"})
+
+	err := p.OnConfigurationChange()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "survey", p.getConfiguration().Trigger)
+}
+
+func TestOnConfigurationChangeRollsBackOnRegisterFailure(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("LoadPluginConfiguration", mock.AnythingOfType("*plugin.configuration")).
+		Run(loadPluginConfigurationInto("survey")).Return(nil)
+	api.On("UnregisterCommand", "", "poll").Return(nil)
+	api.On("RegisterCommand", mock.MatchedBy(func(c *model.Command) bool { return c.Trigger == "survey" })).
+		Return(&model.AppError{})
+	api.On("RegisterCommand", mock.MatchedBy(func(c *model.Command) bool { return c.Trigger == "poll" })).
+		Return(nil)
+	defer api.AssertExpectations(t)
+
+	p := &MatterpollPlugin{}
+	p.SetAPI(api)
+	p.setConfiguration(&configuration{Trigger: "poll"})
+
+	err := p.OnConfigurationChange()
+
+	assert.NotNil(t, err)
+	// The plugin must never be left believing the swap succeeded.
+	assert.Equal(t, "poll", p.getConfiguration().Trigger)
+}
+
+func TestOnConfigurationChangeRejectsInvalidTrigger(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("LoadPluginConfiguration", mock.AnythingOfType("*plugin.configuration")).
+		Run(loadPluginConfigurationInto("")).Return(nil)
+	defer api.AssertExpectations(t)
+
+	p := &MatterpollPlugin{}
+	p.SetAPI(api)
+	p.setConfiguration(&configuration{Trigger: "poll"})
+
+	err := p.OnConfigurationChange()
+
+	assert.NotNil(t, err)
+	// Invalid config must never be applied, and the old trigger stays intact.
+	assert.Equal(t, "poll", p.getConfiguration().Trigger)
+}
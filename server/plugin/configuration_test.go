@@ -0,0 +1,53 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigurationValidate(t *testing.T) {
+	for name, test := range map[string]struct {
+		Configuration configuration
+		ShouldError   bool
+	}{
+		"valid": {
+			Configuration: configuration{Trigger: "poll"},
+			ShouldError:   false,
+		},
+		"empty trigger": {
+			Configuration: configuration{Trigger: ""},
+			ShouldError:   true,
+		},
+		"whitespace-only trigger": {
+			Configuration: configuration{Trigger: "  "},
+			ShouldError:   true,
+		},
+		"trigger with internal whitespace": {
+			Configuration: configuration{Trigger: "po ll"},
+			ShouldError:   true,
+		},
+		"reserved trigger name": {
+			Configuration: configuration{Trigger: "shrug"},
+			ShouldError:   true,
+		},
+		"valid sql store backend": {
+			Configuration: configuration{Trigger: "poll", StoreBackend: storeBackendSQL},
+			ShouldError:   false,
+		},
+		"unknown store backend": {
+			Configuration: configuration{Trigger: "poll", StoreBackend: "mongo"},
+			ShouldError:   true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := test.Configuration.Validate()
+
+			if test.ShouldError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package plugin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/utils/testutils"
+)
+
+func setupTestPluginWithConfig(t *testing.T, api *plugintest.API, store *mockstore.Store, c *configuration) *MatterpollPlugin {
+	p := setupTestPlugin(t, api, store, testutils.GetSiteURL())
+	p.setConfiguration(c)
+	return p
+}
+
+func TestAPICreatePollRejectsDisallowedRole(t *testing.T) {
+	api := &plugintest.API{}
+	store := &mockstore.Store{}
+	p := setupTestPluginWithConfig(t, api, store, &configuration{
+		Trigger:      "poll",
+		AllowedRoles: []string{"system_admin"},
+	})
+
+	api.On("GetUser", "userID1").Return(&model.User{Id: "userID1", Roles: "system_user"}, nil)
+	defer api.AssertExpectations(t)
+
+	body := `{"question":"what now?","answer_options":["a","b"]}`
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/polls", strings.NewReader(body))
+	r.Header.Set("Mattermost-User-Id", "userID1")
+	w := httptest.NewRecorder()
+
+	p.router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestAPICreatePollAllowsMatchingRole(t *testing.T) {
+	api := &plugintest.API{}
+	store := &mockstore.Store{}
+	p := setupTestPluginWithConfig(t, api, store, &configuration{
+		Trigger:      "poll",
+		AllowedRoles: []string{"system_admin"},
+	})
+
+	api.On("GetUser", "userID1").Return(&model.User{Id: "userID1", Roles: "system_user system_admin"}, nil)
+	defer api.AssertExpectations(t)
+
+	store.On("SavePoll", mock.AnythingOfType("*poll.Poll")).Return(nil)
+
+	body := `{"question":"what now?","answer_options":["a","b"]}`
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/polls", strings.NewReader(body))
+	r.Header.Set("Mattermost-User-Id", "userID1")
+	w := httptest.NewRecorder()
+
+	p.router.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	store.AssertExpectations(t)
+}
+
+func TestAPICreatePollAppliesDefaultPollSettings(t *testing.T) {
+	api := &plugintest.API{}
+	store := &mockstore.Store{}
+	p := setupTestPluginWithConfig(t, api, store, &configuration{
+		Trigger:             "poll",
+		DefaultPollSettings: poll.Settings{Anonymous: true, MaxVotes: 3},
+	})
+
+	var saved *poll.Poll
+	store.On("SavePoll", mock.AnythingOfType("*poll.Poll")).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(*poll.Poll)
+	}).Return(nil)
+
+	body := `{"question":"what now?","answer_options":["a","b"]}`
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/polls", strings.NewReader(body))
+	r.Header.Set("Mattermost-User-Id", "userID1")
+	w := httptest.NewRecorder()
+
+	p.router.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.NotNil(t, saved)
+	assert.True(t, saved.Settings.Anonymous)
+	assert.Equal(t, 3, saved.Settings.MaxVotes)
+}
@@ -0,0 +1,104 @@
+package plugin
+
+import (
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+)
+
+// featureRequirement describes the server version window a feature is
+// available in. MaxVersion is empty when the feature, once introduced, is
+// never removed.
+type featureRequirement struct {
+	Feature    string
+	MinVersion string
+	MaxVersion string
+	// Required marks a feature the plugin cannot run without. Activation
+	// fails if the running server falls outside a required feature's window;
+	// for non-required features the plugin just toggles the flag off and
+	// degrades gracefully.
+	Required bool
+}
+
+// featureRequirements is the table OnActivate evaluates against the running
+// server version. It replaces a single hard-coded minimumServerVersion check.
+var featureRequirements = []featureRequirement{
+	{Feature: "BotAccounts", MinVersion: minimumServerVersion, MaxVersion: "", Required: true},
+	{Feature: "DialogElements", MinVersion: "5.12.0", MaxVersion: "", Required: false},
+}
+
+// features records, per server version, which optional capabilities this
+// plugin instance may use. Call sites should consult these instead of
+// comparing server versions themselves.
+type features struct {
+	BotAccounts    bool
+	DialogElements bool
+}
+
+// set toggles the named feature on this struct. Unknown names are ignored,
+// since featureRequirements is the only place new features get added.
+func (f *features) set(name string, enabled bool) {
+	switch name {
+	case "BotAccounts":
+		f.BotAccounts = enabled
+	case "DialogElements":
+		f.DialogElements = enabled
+	}
+}
+
+// evaluateFeatures checks sv against every entry in featureRequirements,
+// toggling p.features accordingly. It returns an error only when a required
+// feature's min/max window is violated, so the plugin fails to activate on
+// servers it truly cannot run on, but degrades gracefully otherwise.
+func (p *MatterpollPlugin) evaluateFeatures(sv semver.Version) error {
+	var f features
+
+	for _, req := range featureRequirements {
+		inRange, err := versionInRange(sv, req.MinVersion, req.MaxVersion)
+		if err != nil {
+			return errors.Wrapf(err, "invalid feature requirement for %s", req.Feature)
+		}
+
+		if !inRange && req.Required {
+			return errors.Errorf("this plugin requires feature %q, which needs server version %s", req.Feature, versionRangeString(req.MinVersion, req.MaxVersion))
+		}
+		f.set(req.Feature, inRange)
+	}
+
+	p.features = f
+	return nil
+}
+
+func versionInRange(sv semver.Version, min, max string) (bool, error) {
+	if min != "" {
+		mv, err := semver.Parse(min)
+		if err != nil {
+			return false, err
+		}
+		if sv.LT(mv) {
+			return false, nil
+		}
+	}
+	if max != "" {
+		mv, err := semver.Parse(max)
+		if err != nil {
+			return false, err
+		}
+		if sv.GT(mv) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func versionRangeString(min, max string) string {
+	switch {
+	case min != "" && max != "":
+		return min + " - " + max
+	case min != "":
+		return min + " or later"
+	case max != "":
+		return max + " or earlier"
+	default:
+		return "any version"
+	}
+}
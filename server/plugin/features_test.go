@@ -0,0 +1,71 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateFeatures(t *testing.T) {
+	for name, test := range map[string]struct {
+		Requirements  []featureRequirement
+		ServerVersion string
+		ShouldError   bool
+		ExpectEnabled map[string]bool
+	}{
+		"required feature, version equal to min": {
+			Requirements:  []featureRequirement{{Feature: "BotAccounts", MinVersion: "5.10.0", Required: true}},
+			ServerVersion: "5.10.0",
+			ShouldError:   false,
+			ExpectEnabled: map[string]bool{"BotAccounts": true},
+		},
+		"required feature, version below min": {
+			Requirements:  []featureRequirement{{Feature: "BotAccounts", MinVersion: "5.10.0", Required: true}},
+			ServerVersion: "5.9.0",
+			ShouldError:   true,
+		},
+		"required feature, version above max": {
+			Requirements:  []featureRequirement{{Feature: "BotAccounts", MinVersion: "5.10.0", MaxVersion: "5.20.0", Required: true}},
+			ServerVersion: "5.21.0",
+			ShouldError:   true,
+		},
+		"optional feature, version below min just disables it": {
+			Requirements:  []featureRequirement{{Feature: "DialogElements", MinVersion: "5.12.0", Required: false}},
+			ServerVersion: "5.11.0",
+			ShouldError:   false,
+			ExpectEnabled: map[string]bool{"DialogElements": false},
+		},
+		"optional feature, version within range enables it": {
+			Requirements:  []featureRequirement{{Feature: "DialogElements", MinVersion: "5.12.0", Required: false}},
+			ServerVersion: "5.15.0",
+			ShouldError:   false,
+			ExpectEnabled: map[string]bool{"DialogElements": true},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			original := featureRequirements
+			featureRequirements = test.Requirements
+			defer func() { featureRequirements = original }()
+
+			p := &MatterpollPlugin{}
+			err := p.evaluateFeatures(semver.MustParse(test.ServerVersion))
+
+			if test.ShouldError {
+				assert.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+
+			for feature, enabled := range test.ExpectEnabled {
+				switch feature {
+				case "BotAccounts":
+					assert.Equal(t, enabled, p.features.BotAccounts)
+				case "DialogElements":
+					assert.Equal(t, enabled, p.features.DialogElements)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,6 @@
+package plugin
+
+// pluginVersion is the plugin's release version. It is passed to
+// kvstore.NewStore, which keeps it on the Store; no stored record is
+// migrated based on it. It is bumped alongside plugin.json.
+const pluginVersion = "1.5.0"
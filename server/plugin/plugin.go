@@ -0,0 +1,132 @@
+// Package plugin implements the Matterpoll Mattermost plugin.
+package plugin
+
+import (
+	"net/http"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/blang/semver"
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+
+	"github.com/matterpoll/matterpoll/server/store"
+	"github.com/matterpoll/matterpoll/server/store/kvstore"
+	"github.com/matterpoll/matterpoll/server/store/sqlstore"
+)
+
+// minimumServerVersion is the oldest Mattermost server this plugin supports.
+const minimumServerVersion = "5.10.0"
+
+// MatterpollPlugin is the top-level plugin object the Mattermost server talks to.
+type MatterpollPlugin struct {
+	plugin.MattermostPlugin
+
+	// ServerConfig is a snapshot of the server's config, refreshed on activation.
+	ServerConfig *model.Config
+
+	configuration atomic.Value
+
+	router *mux.Router
+	bundle *i18n.Bundle
+
+	// features records which optional capabilities are available on the
+	// running server, see evaluateFeatures.
+	features features
+
+	// Store persists polls. Defaults to a kvstore.Store, see OnActivate.
+	Store store.Store
+}
+
+// OnActivate is invoked when the plugin is activated. It verifies the server
+// is new enough, loads translations, sets up the poll store and wires up the
+// REST API router.
+func (p *MatterpollPlugin) OnActivate() error {
+	p.ServerConfig = p.API.GetConfig()
+
+	serverVersion := p.API.GetServerVersion()
+	if serverVersion == "" {
+		return errors.New("this plugin requires Mattermost server version 5.10 or later")
+	}
+
+	sv, err := semver.Parse(serverVersion)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse server version")
+	}
+	if err := p.evaluateFeatures(sv); err != nil {
+		return err
+	}
+
+	bundlePath, err := p.API.GetBundlePath()
+	if err != nil {
+		return errors.Wrap(err, "failed to get bundle path")
+	}
+
+	bundle, err := p.loadTranslations(filepath.Join(bundlePath, "assets", "i18n"))
+	if err != nil {
+		return errors.Wrap(err, "failed to load translations")
+	}
+	p.bundle = bundle
+
+	s, err := p.newStore()
+	if err != nil {
+		return errors.Wrap(err, "failed to create store")
+	}
+	p.Store = s
+
+	p.router = p.InitAPI()
+
+	return nil
+}
+
+// newStore picks a store.Store implementation based on the configured
+// StoreBackend. "kv" (the default, used when StoreBackend is unset) keeps
+// the existing plugin-KV-backed store; "sql" persists polls relationally so
+// the REST API's list endpoints can query and paginate server-side.
+func (p *MatterpollPlugin) newStore() (store.Store, error) {
+	switch p.getConfiguration().StoreBackend {
+	case storeBackendSQL:
+		p.ServerConfig = p.API.GetConfig()
+		return sqlstore.NewStore(p.ServerConfig.SqlSettings)
+	case storeBackendKV, "":
+		return kvstore.NewStore(p.API, pluginVersion)
+	default:
+		return nil, errors.Errorf("unknown store backend %q", p.getConfiguration().StoreBackend)
+	}
+}
+
+// loadTranslations loads the i18n bundle from dir. It is a separate method so
+// tests can exercise OnActivate without shipping real translation files.
+func (p *MatterpollPlugin) loadTranslations(dir string) (*i18n.Bundle, error) {
+	bundle := i18n.NewBundle(language.English)
+	matches, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, errors.Errorf("no translation files found in %s", dir)
+	}
+	for _, m := range matches {
+		if _, err := bundle.LoadMessageFile(m); err != nil {
+			return nil, errors.Wrapf(err, "failed to load translation file %s", m)
+		}
+	}
+	return bundle, nil
+}
+
+// OnDeactivate unregisters the slash command this plugin owns.
+func (p *MatterpollPlugin) OnDeactivate() error {
+	if err := p.API.UnregisterCommand("", p.getConfiguration().Trigger); err != nil {
+		return errors.Wrap(err, "failed to unregister command")
+	}
+	return nil
+}
+
+// ServeHTTP routes incoming HTTP requests to the REST API router set up in OnActivate.
+func (p *MatterpollPlugin) ServeHTTP(c *plugin.Context, w http.ResponseWriter, r *http.Request) {
+	p.router.ServeHTTP(w, r)
+}
@@ -0,0 +1,67 @@
+package plugin
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/bouk/monkey"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/store"
+	"github.com/matterpoll/matterpoll/server/store/mockstore"
+	"github.com/matterpoll/matterpoll/server/store/sqlstore"
+)
+
+// TestPluginOnActivateSQLStore exercises the sql StoreBackend path of
+// OnActivate, mirroring TestPluginOnActivate's "same version as
+// minimumServerVersion" case but with sqlstore.NewStore monkey-patched
+// instead of kvstore.NewStore.
+func TestPluginOnActivateSQLStore(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("GetServerVersion").Return(minimumServerVersion)
+	api.On("GetConfig").Return(&model.Config{})
+	path, err := filepath.Abs("../..")
+	require.Nil(t, err)
+	api.On("GetBundlePath").Return(path, nil)
+	defer api.AssertExpectations(t)
+
+	patch := monkey.Patch(sqlstore.NewStore, func(model.SqlSettings) (store.Store, error) {
+		return &mockstore.Store{}, nil
+	})
+	defer patch.Unpatch()
+
+	p := &MatterpollPlugin{ServerConfig: &model.Config{}}
+	p.setConfiguration(&configuration{
+		Trigger:      "poll",
+		StoreBackend: storeBackendSQL,
+	})
+	p.SetAPI(api)
+
+	err = p.OnActivate()
+
+	assert.Nil(t, err)
+}
+
+func TestPluginOnActivateUnknownStoreBackend(t *testing.T) {
+	api := &plugintest.API{}
+	api.On("GetServerVersion").Return(minimumServerVersion)
+	api.On("GetConfig").Return(&model.Config{})
+	path, err := filepath.Abs("../..")
+	require.Nil(t, err)
+	api.On("GetBundlePath").Return(path, nil)
+	defer api.AssertExpectations(t)
+
+	p := &MatterpollPlugin{ServerConfig: &model.Config{}}
+	p.setConfiguration(&configuration{
+		Trigger:      "poll",
+		StoreBackend: "mongo",
+	})
+	p.SetAPI(api)
+
+	err = p.OnActivate()
+
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,51 @@
+// Package poll contains the data model for a single poll and its votes.
+package poll
+
+// AnswerOption is a single answer a poll can be voted on.
+type AnswerOption struct {
+	Answer string
+	Voter  []string
+}
+
+// Settings controls optional poll behaviour, e.g. whether votes are public.
+type Settings struct {
+	Anonymous       bool
+	Progress        bool
+	PublicAddOption bool
+	MaxVotes        int
+}
+
+// Poll represents a single poll, who created it and how people have voted on it.
+type Poll struct {
+	ID            string
+	CreatedAt     int64
+	Creator       string
+	ChannelID     string
+	Question      string
+	AnswerOptions []*AnswerOption
+	Settings      Settings
+}
+
+// Copy deep copies the poll, so callers can mutate the copy without affecting the stored original.
+func (p *Poll) Copy() *Poll {
+	copy := *p
+	copy.AnswerOptions = make([]*AnswerOption, len(p.AnswerOptions))
+	for i, o := range p.AnswerOptions {
+		oCopy := *o
+		oCopy.Voter = append([]string(nil), o.Voter...)
+		copy.AnswerOptions[i] = &oCopy
+	}
+	return &copy
+}
+
+// HasVoted returns true when userID already voted on this poll.
+func (p *Poll) HasVoted(userID string) bool {
+	for _, o := range p.AnswerOptions {
+		for _, v := range o.Voter {
+			if v == userID {
+				return true
+			}
+		}
+	}
+	return false
+}
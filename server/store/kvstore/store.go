@@ -0,0 +1,103 @@
+// Package kvstore implements store.Store on top of the Mattermost plugin KV API.
+package kvstore
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/mattermost/mattermost-server/plugin"
+	"github.com/pkg/errors"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store"
+)
+
+const pollPrefix = "poll_"
+
+// Store persists polls as individual JSON blobs in the plugin KV store.
+type Store struct {
+	api           plugin.API
+	pluginVersion string
+}
+
+// NewStore constructs a kvstore-backed store.Store.
+func NewStore(api plugin.API, pluginVersion string) (store.Store, error) {
+	return &Store{api: api, pluginVersion: pluginVersion}, nil
+}
+
+func (s *Store) Poll(id string) (*poll.Poll, error) {
+	b, appErr := s.api.KVGet(pollPrefix + id)
+	if appErr != nil {
+		return nil, errors.Wrap(appErr, "failed to get poll")
+	}
+	if b == nil {
+		return nil, errors.Errorf("poll %s not found", id)
+	}
+
+	var p poll.Poll
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal poll")
+	}
+	return &p, nil
+}
+
+func (s *Store) SavePoll(p *poll.Poll) error {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal poll")
+	}
+	if appErr := s.api.KVSet(pollPrefix+p.ID, b); appErr != nil {
+		return errors.Wrap(appErr, "failed to save poll")
+	}
+	return nil
+}
+
+func (s *Store) DeletePoll(id string) error {
+	if appErr := s.api.KVDelete(pollPrefix + id); appErr != nil {
+		return errors.Wrap(appErr, "failed to delete poll")
+	}
+	return nil
+}
+
+// ListPollsForUser scans the KV store for polls created by userID.
+//
+// This is O(n) in the total number of stored polls, since the KV API has no
+// secondary indexes; callers that need efficient querying should prefer the
+// sqlstore backend.
+func (s *Store) ListPollsForUser(userID string) ([]*poll.Poll, error) {
+	var polls []*poll.Poll
+	for page := 0; ; page++ {
+		keys, appErr := s.api.KVList(page, 100)
+		if appErr != nil {
+			return nil, errors.Wrap(appErr, "failed to list keys")
+		}
+		if len(keys) == 0 {
+			break
+		}
+		for _, k := range keys {
+			if len(k) <= len(pollPrefix) || k[:len(pollPrefix)] != pollPrefix {
+				continue
+			}
+			p, err := s.Poll(k[len(pollPrefix):])
+			if err != nil {
+				continue
+			}
+			if p.Creator == userID {
+				polls = append(polls, p)
+			}
+		}
+	}
+
+	// store.Store documents ListPollsForUser as most-recent-first, which the
+	// REST API's cursor pagination relies on; KVList enumerates keys in no
+	// particular order, so sort explicitly by (CreatedAt, Id) descending —
+	// the same total order sqlstore's ORDER BY CreatedAt DESC, Id DESC gives.
+	sort.Slice(polls, func(i, j int) bool {
+		if polls[i].CreatedAt != polls[j].CreatedAt {
+			return polls[i].CreatedAt > polls[j].CreatedAt
+		}
+		return polls[i].ID > polls[j].ID
+	})
+
+	return polls, nil
+}
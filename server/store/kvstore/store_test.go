@@ -0,0 +1,38 @@
+package kvstore
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/plugin/plugintest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+func TestStoreListPollsForUserOrdersMostRecentFirst(t *testing.T) {
+	api := &plugintest.API{}
+	defer api.AssertExpectations(t)
+
+	older := &poll.Poll{ID: "pollID1", CreatedAt: 100, Creator: "userID1"}
+	newer := &poll.Poll{ID: "pollID2", CreatedAt: 200, Creator: "userID1"}
+	olderB, err := json.Marshal(older)
+	require.Nil(t, err)
+	newerB, err := json.Marshal(newer)
+	require.Nil(t, err)
+
+	// KVList enumerates keys in storage order, which is not creation order.
+	api.On("KVList", 0, 100).Return([]string{pollPrefix + "pollID1", pollPrefix + "pollID2"}, nil)
+	api.On("KVList", 1, 100).Return([]string{}, nil)
+	api.On("KVGet", pollPrefix+"pollID1").Return(olderB, nil)
+	api.On("KVGet", pollPrefix+"pollID2").Return(newerB, nil)
+
+	s := &Store{api: api}
+	polls, err := s.ListPollsForUser("userID1")
+
+	require.Nil(t, err)
+	require.Len(t, polls, 2)
+	assert.Equal(t, "pollID2", polls[0].ID)
+	assert.Equal(t, "pollID1", polls[1].ID)
+}
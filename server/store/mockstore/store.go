@@ -0,0 +1,41 @@
+// Package mockstore provides a testify mock implementation of store.Store for use in tests.
+package mockstore
+
+import (
+	"github.com/stretchr/testify/mock"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+// Store is a mock.Mock-backed store.Store, generated by hand to mirror the real interface.
+type Store struct {
+	mock.Mock
+}
+
+func (s *Store) Poll(id string) (*poll.Poll, error) {
+	args := s.Called(id)
+	var p *poll.Poll
+	if args.Get(0) != nil {
+		p = args.Get(0).(*poll.Poll)
+	}
+	return p, args.Error(1)
+}
+
+func (s *Store) SavePoll(p *poll.Poll) error {
+	args := s.Called(p)
+	return args.Error(0)
+}
+
+func (s *Store) DeletePoll(id string) error {
+	args := s.Called(id)
+	return args.Error(0)
+}
+
+func (s *Store) ListPollsForUser(userID string) ([]*poll.Poll, error) {
+	args := s.Called(userID)
+	var polls []*poll.Poll
+	if args.Get(0) != nil {
+		polls = args.Get(0).([]*poll.Poll)
+	}
+	return polls, args.Error(1)
+}
@@ -0,0 +1,195 @@
+// Package sqlstore implements store.Store on top of a relational database,
+// so polls can be queried server-side (by creator, by channel, aggregate
+// stats) instead of scanned key-by-key as the kvstore does.
+package sqlstore
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	// Drivers are imported for side effects only; DriverName picks between them.
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/pkg/errors"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+	"github.com/matterpoll/matterpoll/server/store"
+)
+
+// Store persists polls in a "polls" table via database/sql. Query text
+// differs by driverName (placeholder style and upsert syntax aren't
+// portable across MySQL/Postgres), so every statement is built through
+// s.rebind or a driver switch rather than shared verbatim.
+type Store struct {
+	db         *sql.DB
+	driverName string
+}
+
+// NewStore opens a connection using the server's own SqlSettings and runs
+// migrations. The returned store.Store is ready to use immediately.
+func NewStore(sqlSettings model.SqlSettings) (store.Store, error) {
+	driverName := ""
+	if sqlSettings.DriverName != nil {
+		driverName = *sqlSettings.DriverName
+	}
+	dataSource := ""
+	if sqlSettings.DataSource != nil {
+		dataSource = *sqlSettings.DataSource
+	}
+	if driverName == "" || dataSource == "" {
+		return nil, errors.New("SqlSettings.DriverName and DataSource must be set to use the sql store backend")
+	}
+	switch driverName {
+	case model.DATABASE_DRIVER_MYSQL, model.DATABASE_DRIVER_POSTGRES:
+	default:
+		return nil, errors.Errorf("sql store backend does not support driver %q", driverName)
+	}
+
+	db, err := sql.Open(driverName, dataSource)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open database connection")
+	}
+	if err := db.Ping(); err != nil {
+		return nil, errors.Wrap(err, "failed to ping database")
+	}
+
+	s := &Store{db: db, driverName: driverName}
+	if err := s.migrate(); err != nil {
+		return nil, errors.Wrap(err, "failed to run migrations")
+	}
+	return s, nil
+}
+
+// rebind rewrites a query written with "?" placeholders into the dialect
+// s.driverName expects. MySQL already uses "?"; Postgres wants "$1", "$2", ...
+func (s *Store) rebind(query string) string {
+	if s.driverName != model.DATABASE_DRIVER_POSTGRES {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// migrate creates the polls table if it doesn't already exist. It is safe to
+// call on every activation.
+func (s *Store) migrate() error {
+	_, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS Polls (
+			Id VARCHAR(26) PRIMARY KEY,
+			CreatedAt BIGINT NOT NULL,
+			CreatorId VARCHAR(26) NOT NULL,
+			ChannelId TEXT NOT NULL,
+			Data TEXT NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	if s.driverName == model.DATABASE_DRIVER_MYSQL {
+		// MySQL has no CREATE INDEX IF NOT EXISTS; ignore the "duplicate key
+		// name" error it raises when the index is already there.
+		if _, err := s.db.Exec(`CREATE INDEX idx_polls_creator_id ON Polls (CreatorId)`); err != nil &&
+			!strings.Contains(err.Error(), "Duplicate key name") {
+			return err
+		}
+		return nil
+	}
+
+	_, err = s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_polls_creator_id ON Polls (CreatorId)`)
+	return err
+}
+
+// upsertQuery returns the dialect-specific "insert or update" statement for
+// SavePoll: MySQL has no ON CONFLICT, and Postgres has no ON DUPLICATE KEY.
+func (s *Store) upsertQuery() string {
+	if s.driverName == model.DATABASE_DRIVER_MYSQL {
+		return `
+			INSERT INTO Polls (Id, CreatedAt, CreatorId, ChannelId, Data) VALUES (?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE CreatedAt = VALUES(CreatedAt), CreatorId = VALUES(CreatorId), ChannelId = VALUES(ChannelId), Data = VALUES(Data)
+		`
+	}
+	return s.rebind(`
+		INSERT INTO Polls (Id, CreatedAt, CreatorId, ChannelId, Data) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (Id) DO UPDATE SET CreatedAt = excluded.CreatedAt, CreatorId = excluded.CreatorId, ChannelId = excluded.ChannelId, Data = excluded.Data
+	`)
+}
+
+func (s *Store) Poll(id string) (*poll.Poll, error) {
+	var data string
+	row := s.db.QueryRow(s.rebind(`SELECT Data FROM Polls WHERE Id = ?`), id)
+	if err := row.Scan(&data); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Errorf("poll %s not found", id)
+		}
+		return nil, errors.Wrap(err, "failed to get poll")
+	}
+
+	var p poll.Poll
+	if err := json.Unmarshal([]byte(data), &p); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal poll")
+	}
+	return &p, nil
+}
+
+func (s *Store) SavePoll(p *poll.Poll) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal poll")
+	}
+
+	_, err = s.db.Exec(s.upsertQuery(), p.ID, p.CreatedAt, p.Creator, p.ChannelID, string(data))
+	if err != nil {
+		return errors.Wrap(err, "failed to save poll")
+	}
+	return nil
+}
+
+func (s *Store) DeletePoll(id string) error {
+	if _, err := s.db.Exec(s.rebind(`DELETE FROM Polls WHERE Id = ?`), id); err != nil {
+		return errors.Wrap(err, "failed to delete poll")
+	}
+	return nil
+}
+
+// ListPollsForUser is the whole reason this backend exists: unlike kvstore,
+// it can ask the database for exactly the rows it needs instead of scanning
+// every key.
+func (s *Store) ListPollsForUser(userID string) ([]*poll.Poll, error) {
+	// CreatedAt alone doesn't give a total order (it's a Unix-second
+	// timestamp and polls can share a second), so tie-break on Id the same
+	// way kvstore sorts, keeping cursor pagination stable across backends.
+	rows, err := s.db.Query(s.rebind(`SELECT Data FROM Polls WHERE CreatorId = ? ORDER BY CreatedAt DESC, Id DESC`), userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list polls")
+	}
+	defer rows.Close()
+
+	var polls []*poll.Poll
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, errors.Wrap(err, "failed to scan poll row")
+		}
+		var p poll.Poll
+		if err := json.Unmarshal([]byte(data), &p); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal poll")
+		}
+		polls = append(polls, &p)
+	}
+	return polls, rows.Err()
+}
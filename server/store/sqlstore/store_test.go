@@ -0,0 +1,72 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/matterpoll/matterpoll/server/poll"
+)
+
+func newTestStore(t *testing.T) (*Store, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.Nil(t, err)
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS Polls").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_polls_creator_id").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	s := &Store{db: db, driverName: "sqlmock"}
+	require.Nil(t, s.migrate())
+	return s, mock
+}
+
+func TestStoreSavePoll(t *testing.T) {
+	s, mock := newTestStore(t)
+
+	p := &poll.Poll{ID: "pollID1", Creator: "userID1"}
+	mock.ExpectExec("INSERT INTO Polls").WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := s.SavePoll(p)
+
+	assert.Nil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestStorePollNotFound(t *testing.T) {
+	s, mock := newTestStore(t)
+
+	mock.ExpectQuery("SELECT Data FROM Polls").WithArgs("pollID1").WillReturnError(sql.ErrNoRows)
+
+	_, err := s.Poll("pollID1")
+
+	assert.NotNil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestStoreRebindUsesDollarPlaceholdersForPostgres(t *testing.T) {
+	s := &Store{driverName: "postgres"}
+
+	assert.Equal(t, "SELECT Data FROM Polls WHERE Id = $1", s.rebind("SELECT Data FROM Polls WHERE Id = ?"))
+	assert.Equal(t, "a = $1 AND b = $2", s.rebind("a = ? AND b = ?"))
+}
+
+func TestStoreRebindLeavesQuestionMarksForMySQL(t *testing.T) {
+	s := &Store{driverName: "mysql"}
+
+	assert.Equal(t, "SELECT Data FROM Polls WHERE Id = ?", s.rebind("SELECT Data FROM Polls WHERE Id = ?"))
+}
+
+func TestStoreUpsertQueryIsDialectSpecific(t *testing.T) {
+	mysql := &Store{driverName: "mysql"}
+	assert.Contains(t, mysql.upsertQuery(), "ON DUPLICATE KEY UPDATE")
+	assert.Contains(t, mysql.upsertQuery(), "?")
+	assert.NotContains(t, mysql.upsertQuery(), "ON CONFLICT")
+
+	postgres := &Store{driverName: "postgres"}
+	assert.Contains(t, postgres.upsertQuery(), "ON CONFLICT")
+	assert.Contains(t, postgres.upsertQuery(), "$1")
+	assert.NotContains(t, postgres.upsertQuery(), "ON DUPLICATE KEY")
+}
@@ -0,0 +1,18 @@
+// Package store defines the persistence interface used by the plugin to store polls.
+package store
+
+import "github.com/matterpoll/matterpoll/server/poll"
+
+// Store abstracts how polls are persisted. Implementations live in sibling packages
+// (kvstore, mockstore, sqlstore) so the plugin can swap backends without touching
+// call sites.
+type Store interface {
+	// Poll returns the poll with the given id, or an error if it doesn't exist.
+	Poll(id string) (*poll.Poll, error)
+	// SavePoll creates or overwrites a poll.
+	SavePoll(p *poll.Poll) error
+	// DeletePoll removes a poll by id.
+	DeletePoll(id string) error
+	// ListPollsForUser returns the polls created by userID, most recent first.
+	ListPollsForUser(userID string) ([]*poll.Poll, error)
+}
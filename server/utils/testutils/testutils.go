@@ -0,0 +1,7 @@
+// Package testutils holds small fixtures shared across the plugin's test suites.
+package testutils
+
+// GetSiteURL returns a fixed SiteURL used to construct a *model.Config in tests.
+func GetSiteURL() string {
+	return "https://example.org"
+}